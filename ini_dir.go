@@ -0,0 +1,250 @@
+package ini
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"gopkg.in/fsnotify.v1"
+	"gopkg.in/ini.v1"
+)
+
+// DirOp describes what happened to a file within a watched IniDir.
+type DirOp int
+
+const (
+	// DirFileAdded is fired the first time a matching file is seen.
+	DirFileAdded DirOp = iota
+	// DirFileChanged is fired when a known file's mtime advances.
+	DirFileChanged
+	// DirFileRemoved is fired when a known file disappears.
+	DirFileRemoved
+)
+
+func (op DirOp) String() string {
+	switch op {
+	case DirFileAdded:
+		return "added"
+	case DirFileChanged:
+		return "changed"
+	case DirFileRemoved:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// DirEvent describes a single add/change/remove of a file within a
+// directory watched via NewIniDir.
+type DirEvent struct {
+	Op   DirOp
+	Name string    // base name of the file within the directory
+	File *ini.File // nil when Op == DirFileRemoved
+}
+
+type dirUpdateFunc func(evt DirEvent)
+
+// IniDir is a live view over a directory of INI fragments, e.g. a
+// `conf.d`-style drop-in directory. Each fragment is kept as its own
+// *ini.File; their sections are not merged into a single config.
+type IniDir interface {
+	// Files returns the current set of parsed files, keyed by base name.
+	Files() map[string]*ini.File
+	// RegisterDir registers f to be called on every add/change/remove
+	// of a matching file within the directory.
+	RegisterDir(f dirUpdateFunc)
+}
+
+type dirEntry struct {
+	cfg     *ini.File
+	modTime time.Time
+}
+
+type dirDetails struct {
+	path string
+	glob string
+
+	lock    sync.RWMutex
+	entries map[string]*dirEntry
+
+	watchersLock sync.RWMutex
+	watchers     []dirUpdateFunc
+}
+
+var iniDirer map[string]IniDir
+
+// NewIniDir watches path for files matching glob (e.g. "*.ini") and
+// returns a live view over them. The returned IniDir keeps a
+// map[filename]*ini.File cache keyed by mod-time, only reparsing a file
+// when its mtime changes, and fires per-file add/change/remove callbacks
+// registered via RegisterDir.
+func NewIniDir(path string, glob string) (IniDir, error) {
+	key := filepath.Join(path, glob)
+	if d, _ := iniDirer[key]; d != nil {
+		return d, nil
+	}
+
+	dd := &dirDetails{
+		path:    path,
+		glob:    glob,
+		entries: make(map[string]*dirEntry),
+	}
+
+	if err := dd.scan(false); err != nil {
+		return nil, err
+	}
+
+	if err := dd.keepWatch(); err != nil {
+		return nil, err
+	}
+
+	if iniDirer == nil {
+		iniDirer = make(map[string]IniDir)
+	}
+	iniDirer[key] = dd
+	return dd, nil
+}
+
+func (dd *dirDetails) matches(name string) bool {
+	ok, err := filepath.Match(dd.glob, filepath.Base(name))
+	return err == nil && ok
+}
+
+// scan loads every glob-matching file under dd.path, reparsing only the
+// ones whose mtime advanced since the last scan. When fire is true,
+// add/change events are reported to registered callbacks.
+func (dd *dirDetails) scan(fire bool) error {
+	matches, err := filepath.Glob(filepath.Join(dd.path, dd.glob))
+	if err != nil {
+		return fmt.Errorf("failed to glob ini dir %s: %v", dd.path, err)
+	}
+
+	for _, match := range matches {
+		if err := dd.loadFile(match, fire); err != nil {
+			glog.Errorf("Unable to load %s: %v", match, err)
+		}
+	}
+	return nil
+}
+
+func (dd *dirDetails) loadFile(fullPath string, fire bool) error {
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(fullPath)
+
+	dd.lock.RLock()
+	existing := dd.entries[name]
+	dd.lock.RUnlock()
+
+	if existing != nil && !info.ModTime().After(existing.modTime) {
+		return nil
+	}
+
+	cfg, err := ini.Load(fullPath)
+	if err != nil {
+		return fmt.Errorf("failed load config file, err: %v", err)
+	}
+
+	dd.lock.Lock()
+	dd.entries[name] = &dirEntry{cfg: cfg, modTime: info.ModTime()}
+	dd.lock.Unlock()
+
+	if fire {
+		op := DirFileAdded
+		if existing != nil {
+			op = DirFileChanged
+		}
+		dd.notify(DirEvent{Op: op, Name: name, File: cfg})
+	}
+	return nil
+}
+
+func (dd *dirDetails) removeFile(name string, fire bool) {
+	dd.lock.Lock()
+	_, ok := dd.entries[name]
+	delete(dd.entries, name)
+	dd.lock.Unlock()
+
+	if ok && fire {
+		dd.notify(DirEvent{Op: DirFileRemoved, Name: name})
+	}
+}
+
+func (dd *dirDetails) notify(evt DirEvent) {
+	dd.watchersLock.RLock()
+	defer dd.watchersLock.RUnlock()
+	for _, cb := range dd.watchers {
+		go cb(evt)
+	}
+}
+
+func (dd *dirDetails) Files() map[string]*ini.File {
+	dd.lock.RLock()
+	defer dd.lock.RUnlock()
+
+	files := make(map[string]*ini.File, len(dd.entries))
+	for name, entry := range dd.entries {
+		files[name] = entry.cfg
+	}
+	return files
+}
+
+func (dd *dirDetails) RegisterDir(f dirUpdateFunc) {
+	dd.watchersLock.Lock()
+	defer dd.watchersLock.Unlock()
+	dd.watchers = append(dd.watchers, f)
+}
+
+func (dd *dirDetails) keepWatch() error {
+	watchman, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create new watcher for INI dir err: %v", err)
+	}
+
+	if err = watchman.Add(dd.path); err != nil {
+		return fmt.Errorf("Failed add new watcher for INI dir err: %v", err)
+	}
+
+	go dd.watchLoop(watchman)
+	return nil
+}
+
+func (dd *dirDetails) watchLoop(watchman *fsnotify.Watcher) {
+	defer watchman.Close()
+
+	for {
+		select {
+		case event, ok := <-watchman.Events:
+			if !ok {
+				return
+			}
+			if !dd.matches(event.Name) {
+				glog.V(3).Infof("Ignoring dir event for non-matching file: %s", event.Name)
+				continue
+			}
+
+			switch {
+			case event.Op&(fsnotify.Create|fsnotify.Write) != 0:
+				if err := dd.loadFile(event.Name, true); err != nil {
+					glog.Errorf("Unable to load %s: %v", event.Name, err)
+				}
+			case event.Op&(fsnotify.Remove|fsnotify.Rename) != 0:
+				dd.removeFile(filepath.Base(event.Name), true)
+			default:
+				glog.V(3).Infof("Ignoring dir event: %s", event.String())
+			}
+
+		case err, ok := <-watchman.Errors:
+			if !ok {
+				return
+			}
+			glog.Errorf("Watcher error on dir %s: %v", dd.path, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package ini
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestNewIniDirDiscoversFragmentsAndAdds covers chunk0-2: NewIniDir
+// should pick up every matching fragment already in the directory, and
+// fire a DirFileAdded event when a new one appears.
+func TestNewIniDirDiscoversFragmentsAndAdds(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "a.ini"), []byte("[A]\nX = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "b.ini"), []byte("[B]\nY = 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Should be ignored: doesn't match the "*.ini" glob.
+	if err := ioutil.WriteFile(filepath.Join(dir, "c.conf"), []byte("[C]\nZ = 3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	iniDir, err := NewIniDir(dir, "*.ini")
+	if err != nil {
+		t.Fatalf("NewIniDir: %v", err)
+	}
+
+	files := iniDir.Files()
+	if len(files) != 2 {
+		t.Fatalf("expected 2 discovered files, got %d: %v", len(files), files)
+	}
+	if _, ok := files["a.ini"]; !ok {
+		t.Fatal("expected a.ini to be discovered")
+	}
+	if _, ok := files["b.ini"]; !ok {
+		t.Fatal("expected b.ini to be discovered")
+	}
+
+	events := make(chan DirEvent, 1)
+	iniDir.RegisterDir(func(evt DirEvent) {
+		select {
+		case events <- evt:
+		default:
+		}
+	})
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "c.ini"), []byte("[C]\nZ = 3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case evt := <-events:
+		if evt.Op != DirFileAdded || evt.Name != "c.ini" {
+			t.Fatalf("unexpected event: %+v", evt)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("did not receive DirFileAdded event for c.ini")
+	}
+}
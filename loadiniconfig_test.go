@@ -0,0 +1,28 @@
+package ini
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadIniConfigKeepWatch guards against a regression where
+// LoadIniConfig left fileDetails.source nil, so calling KeepWatch on the
+// result panicked on a nil Source instead of watching the file.
+func TestLoadIniConfigKeepWatch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.ini")
+	if err := ioutil.WriteFile(path, []byte("[Server]\nPort = 8080\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg testServerConfig
+	f, err := LoadIniConfig(path, &cfg)
+	if err != nil {
+		t.Fatalf("LoadIniConfig: %v", err)
+	}
+
+	if err := f.KeepWatch(); err != nil {
+		t.Fatalf("KeepWatch: %v", err)
+	}
+}
@@ -3,9 +3,9 @@ package ini
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/golang/glog"
-	"gopkg.in/fsnotify.v1"
 	"gopkg.in/ini.v1"
 )
 
@@ -17,6 +17,25 @@ type IniFile interface {
 	Register(f updateFunc)
 	KeepWatch() error
 	MapContents(v interface{}) error
+	// RegisterTyped maps the file's contents onto v and invokes cb with
+	// the old and new values whenever a reload actually changes them.
+	RegisterTyped(v interface{}, cb func(old, new interface{}) error) error
+	// Changed reports whether section/key's value changed on the most
+	// recent reload.
+	Changed(section, key string) bool
+	// Diff returns the section/key changes observed on the most recent
+	// reload.
+	Diff() []KeyChange
+	// SetSchema installs a validation schema; a load or writeback that
+	// would violate it is rejected.
+	SetSchema(schema Schema)
+	// WriteKey sets section/key to value and persists the change.
+	WriteKey(section, key, value string) error
+	// SetSection sets every key in kv within section and persists the
+	// change.
+	SetSection(name string, kv map[string]string) error
+	// Save persists the in-memory config to fileName.
+	Save() error
 }
 
 var (
@@ -24,23 +43,83 @@ var (
 	mutex    sync.RWMutex
 )
 
+const (
+	// defaultDebounce is how long the watch loop waits for more source
+	// change signals before reloading, collapsing bursts of events into
+	// one reload.
+	defaultDebounce = 200 * time.Millisecond
+	// defaultRescanInterval is how often a source falls back to polling
+	// for changes, in case its native watch mechanism misses an event.
+	defaultRescanInterval = time.Minute
+)
+
 type fileDetails struct {
-	fileName string
-	watch    bool      //True if file is being watched
-	cfg      *ini.File //details added after file is loaded by ini pkg.
-	lock     sync.RWMutex
+	fileName       string
+	source         Source
+	watch          bool      //True if file is being watched
+	cfg            *ini.File //details added after file is loaded by ini pkg.
+	lock           sync.RWMutex
+	debounce       time.Duration
+	rescanInterval time.Duration
+
+	diffLock sync.RWMutex
+	lastDiff []KeyChange
+
+	typedLock     sync.Mutex
+	typedWatchers []*typedWatcher
+
+	schema *Schema
 }
 
 var iniFiler map[string]IniFile
 
-// Loads a new INI file and optionally watches file for changes
-func NewIniFile(fileName string) (IniFile, error) {
+// Option configures optional behavior of a watched IniFile.
+type Option func(*fileDetails)
+
+// WithDebounce sets the window the watch loop waits after a source
+// change signal before reloading, so a burst of changes (editor saves,
+// `cp -f`, an atomic rename via temp file) triggers a single reload
+// instead of one per event. The default is 200ms.
+func WithDebounce(d time.Duration) Option {
+	return func(fd *fileDetails) {
+		fd.debounce = d
+	}
+}
+
+// WithRescanInterval sets how often a file source stats the file's mtime
+// as a fallback reload path, in case fsnotify misses an event. The
+// default is 1 minute; a value of 0 disables the periodic rescan. Other
+// source types interpret this as their own poll interval.
+func WithRescanInterval(d time.Duration) Option {
+	return func(fd *fileDetails) {
+		fd.rescanInterval = d
+	}
+}
+
+// Loads a new INI file and starts watching it for changes. fileName
+// may be a filesystem path or a URL understood by a registered Source,
+// e.g. "http://host/app.ini" or a scheme added via RegisterSource.
+func NewIniFile(fileName string, opts ...Option) (IniFile, error) {
 
 	if f, _ := iniFiler[fileName]; f != nil {
 		return f, nil
 	}
 
-	fd := &fileDetails{fileName: fileName}
+	fd := &fileDetails{
+		fileName:       fileName,
+		debounce:       defaultDebounce,
+		rescanInterval: defaultRescanInterval,
+	}
+	for _, opt := range opts {
+		opt(fd)
+	}
+
+	source, err := newSource(fileName, fd.rescanInterval)
+	if err != nil {
+		return nil, err
+	}
+	fd.source = source
+
 	if err := fd.load(); err != nil {
 		return nil, err
 	}
@@ -56,18 +135,38 @@ func NewIniFile(fileName string) (IniFile, error) {
 }
 
 func (fd *fileDetails) load() error {
-	var err error
+	data, err := fd.source.Read()
+	if err != nil {
+		glog.Errorf("Unable to load %s", fd.source.Name())
+		return err
+	}
+
 	fd.lock.Lock()
 	defer fd.lock.Unlock()
 
-	fd.cfg, err = ini.Load(fd.fileName)
+	before := snapshotSections(fd.cfg)
+
+	cfg, err := ini.Load(data)
 	if err != nil {
-		glog.Errorf("Unable to load the file %s", fd.fileName)
+		glog.Errorf("Unable to parse %s", fd.source.Name())
 		return err
 	}
+
+	if fd.schema != nil {
+		if err := fd.schema.Validate(cfg); err != nil {
+			glog.Errorf("Rejecting reload of %s, keeping previous config: %v", fd.source.Name(), err)
+			return err
+		}
+	}
+	fd.cfg = cfg
+
+	fd.diffLock.Lock()
+	fd.lastDiff = diffSections(before, snapshotSections(fd.cfg))
+	fd.diffLock.Unlock()
+
 	if !fd.watch {
-		glog.Info("Starting watch on file: %s", fd.fileName)
-		fd.keepWatch()
+		glog.Info("Starting watch on: %s", fd.source.Name())
+		fd.startWatch()
 	}
 
 	return nil
@@ -84,46 +183,14 @@ func (fd *fileDetails) ReadKey(section, key, defaultVal string) string {
 	return defaultVal
 }
 
-func (fd *fileDetails) keepWatch() error {
-	watchman, err := fsnotify.NewWatcher()
-	if err != nil {
-		return err
-	}
-
-	glog.V(3).Infof("Watching file %s", fd.fileName)
-
-	go func() {
-		for {
-			select {
-			case event := <-watchman.Events:
-				glog.V(3).Infoln("Received file watch event: %s", event.String())
-				if event.Op&fsnotify.Write == fsnotify.Write {
-					glog.V(3).Infof("modified file: %s", event.Name)
-					fd.load()
-					fd.update()
-				} else {
-					glog.V(3).Infof("Ignoring file event: %s", event.String())
-					continue
-				}
-			}
-		}
-	}()
-
-	err = watchman.Add(fd.fileName)
-	if err != nil {
-		glog.Error("Failed to watch file %s: %v", fd.fileName, err)
-		return err
-	}
-	fd.watch = true
-	return nil
-}
-
 func (fd *fileDetails) update() {
 
 	mutex.Lock()
 	defer mutex.Unlock()
-	updateFunc := updaters[fd.fileName]
 
+	fd.notifyTyped()
+
+	updateFunc := updaters[fd.fileName]
 	if updateFunc == nil {
 		glog.V(3).Infof("Update function not found for %s", fd.fileName)
 		return
@@ -150,7 +217,13 @@ func LoadIniConfig(fileName string, c interface{}) (IniFile, error) {
 		return nil, fmt.Errorf("failed load config file, err: %v", err)
 	}
 
-	f := &fileDetails{fileName: fileName, cfg: cfg}
+	f := &fileDetails{
+		fileName:       fileName,
+		cfg:            cfg,
+		source:         newFileSource(fileName, defaultRescanInterval),
+		debounce:       defaultDebounce,
+		rescanInterval: defaultRescanInterval,
+	}
 	if err = f.MapContents(c); err != nil {
 		return nil, fmt.Errorf("failed to map cfg err: %v", err)
 	}
@@ -159,8 +232,20 @@ func LoadIniConfig(fileName string, c interface{}) (IniFile, error) {
 
 // maps the content of INI file specified in fd to v.
 func (fd *fileDetails) MapContents(v interface{}) error {
-	var err error
-	fd.cfg, err = ini.Load(fd.fileName)
+	if fd.source == nil {
+		var err error
+		fd.cfg, err = ini.Load(fd.fileName)
+		if err != nil {
+			return fmt.Errorf("failed load config file, err: %v", err)
+		}
+		return fd.cfg.MapTo(v)
+	}
+
+	data, err := fd.source.Read()
+	if err != nil {
+		return fmt.Errorf("failed load config file, err: %v", err)
+	}
+	fd.cfg, err = ini.Load(data)
 	if err != nil {
 		return fmt.Errorf("failed load config file, err: %v", err)
 	}
@@ -168,29 +253,56 @@ func (fd *fileDetails) MapContents(v interface{}) error {
 }
 
 func (fd *fileDetails) KeepWatch() error {
-	return fd.newKeepWatch()
+	return fd.startWatch()
 }
 
-func (fd *fileDetails) newKeepWatch() error {
-	watchman, err := fsnotify.NewWatcher()
+// startWatch starts the underlying source's watch and coalesces its
+// change signals in watchLoop.
+func (fd *fileDetails) startWatch() error {
+	if fd.source == nil {
+		return fmt.Errorf("ini file %s has no source to watch", fd.fileName)
+	}
+
+	changes, err := fd.source.Watch()
 	if err != nil {
-		return fmt.Errorf("failed to create new watcher for INI file err: %v", err)
-	}
-	go func() {
-		for {
-			select {
-			case event := <-watchman.Events:
-				if event.Op&fsnotify.Write == fsnotify.Write {
-					fd.update() // callBackRegistered
-				} else {
-					continue // ignore file event if its not Write
+		return fmt.Errorf("failed to watch %s: %v", fd.source.Name(), err)
+	}
+
+	fd.watch = true
+	go fd.watchLoop(changes)
+	return nil
+}
+
+// watchLoop coalesces bursts of source change signals within fd.debounce
+// into a single reload. Deciding *whether* something changed (inotify
+// events, mtime rescans, ETag polling, ...) is the Source's job; this
+// loop only decides *when* to act on it.
+func (fd *fileDetails) watchLoop(changes <-chan struct{}) {
+	pending := make(chan struct{}, 1)
+	var timer *time.Timer
+
+	for {
+		select {
+		case _, ok := <-changes:
+			if !ok {
+				return
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(fd.debounce, func() {
+				select {
+				case pending <- struct{}{}:
+				default:
 				}
+			})
+
+		case <-pending:
+			if err := fd.load(); err != nil {
+				glog.Errorf("Failed to reload %s: %v", fd.source.Name(), err)
+				continue
 			}
+			fd.update()
 		}
-	}()
-	err = watchman.Add(fd.fileName)
-	if err != nil {
-		return fmt.Errorf("Failed add new watcher for INI file err: %v", err)
 	}
-	return nil
 }
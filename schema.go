@@ -0,0 +1,97 @@
+package ini
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// FieldType constrains the value of a schema Field.
+type FieldType int
+
+const (
+	// TypeString accepts any value.
+	TypeString FieldType = iota
+	TypeInt
+	TypeBool
+	TypeDuration
+	// TypeEnum requires the value to be one of Field.Enum.
+	TypeEnum
+)
+
+// Field describes the constraints on a single key within a section.
+type Field struct {
+	Key      string
+	Type     FieldType
+	Required bool
+	// Enum lists the valid values when Type is TypeEnum.
+	Enum []string
+}
+
+// SectionSchema describes the constraints on a single section.
+type SectionSchema struct {
+	Name     string
+	Required bool
+	Fields   []Field
+}
+
+// Schema declares the required sections/keys and value constraints an
+// IniFile must satisfy. Set one with SetSchema to reject a load or
+// writeback that would violate it.
+type Schema struct {
+	Sections []SectionSchema
+}
+
+// Validate checks cfg against every section/field declared in s.
+func (s Schema) Validate(cfg *ini.File) error {
+	for _, sec := range s.Sections {
+		if !cfg.HasSection(sec.Name) {
+			if sec.Required {
+				return fmt.Errorf("missing required section [%s]", sec.Name)
+			}
+			continue
+		}
+
+		section := cfg.Section(sec.Name)
+		for _, field := range sec.Fields {
+			val := section.Key(field.Key).String()
+			if val == "" {
+				if field.Required {
+					return fmt.Errorf("missing required key %q in section [%s]", field.Key, sec.Name)
+				}
+				continue
+			}
+			if err := field.validate(val); err != nil {
+				return fmt.Errorf("key %q in section [%s]: %v", field.Key, sec.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (f Field) validate(val string) error {
+	switch f.Type {
+	case TypeInt:
+		if _, err := strconv.Atoi(val); err != nil {
+			return fmt.Errorf("expected int, got %q", val)
+		}
+	case TypeBool:
+		if _, err := strconv.ParseBool(val); err != nil {
+			return fmt.Errorf("expected bool, got %q", val)
+		}
+	case TypeDuration:
+		if _, err := time.ParseDuration(val); err != nil {
+			return fmt.Errorf("expected duration, got %q", val)
+		}
+	case TypeEnum:
+		for _, allowed := range f.Enum {
+			if val == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected one of %v, got %q", f.Enum, val)
+	}
+	return nil
+}
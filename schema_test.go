@@ -0,0 +1,48 @@
+package ini
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestSchemaRejectsBadReload covers the on-load half of chunk0-5: a
+// reload that would violate the schema must be rejected and the last
+// known-good config kept, instead of leaving readers looking at a
+// broken config.
+func TestSchemaRejectsBadReload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.ini")
+	if err := ioutil.WriteFile(path, []byte("[Server]\nPort = 8080\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := NewIniFile(path, WithDebounce(50*time.Millisecond), WithRescanInterval(0))
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+
+	f.SetSchema(Schema{
+		Sections: []SectionSchema{
+			{
+				Name:     "Server",
+				Required: true,
+				Fields: []Field{
+					{Key: "Port", Type: TypeInt, Required: true},
+				},
+			},
+		},
+	})
+
+	// Violates the schema: Port is no longer an int.
+	if err := ioutil.WriteFile(path, []byte("[Server]\nPort = not-a-number\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(400 * time.Millisecond)
+
+	if got := f.ReadKey("Server", "Port", ""); got != "8080" {
+		t.Fatalf("expected invalid reload to be rejected and old value kept, got %q", got)
+	}
+}
@@ -0,0 +1,62 @@
+package ini
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Source abstracts where an INI file's bytes come from, so NewIniFile
+// can hot-reload config pulled from a local file, a remote HTTP endpoint,
+// or a KV store such as etcd/consul, without the rest of the package
+// caring which.
+type Source interface {
+	// Read returns the current raw contents of the source.
+	Read() ([]byte, error)
+	// Watch starts watching the source for changes and returns a
+	// channel that receives a value whenever the source may have
+	// changed. The channel is closed if watching stops permanently.
+	Watch() (<-chan struct{}, error)
+	// Name identifies the source for logging, e.g. a path or URL.
+	Name() string
+}
+
+// SourceFactory builds a Source from a raw, scheme-prefixed identifier
+// such as "consul://kv/myapp.ini". Register one with RegisterSource to
+// let NewIniFile accept that scheme.
+type SourceFactory func(rawURL string, rescanInterval time.Duration) (Source, error)
+
+var sourceFactories map[string]SourceFactory
+
+// RegisterSource adds support for a URL scheme (e.g. "consul", "etcd") to
+// NewIniFile. This package itself handles only plain filesystem paths and
+// the "http" and "https" schemes; any other scheme, "file" included,
+// needs a registered factory. Remote KV backends are expected to register their own factory so this
+// module doesn't have to vendor their client libraries.
+func RegisterSource(scheme string, factory SourceFactory) {
+	if sourceFactories == nil {
+		sourceFactories = make(map[string]SourceFactory)
+	}
+	sourceFactories[scheme] = factory
+}
+
+// newSource picks a Source for nameOrURL. Anything that doesn't parse as
+// an absolute URL (or that parses with a single-letter scheme, i.e. a
+// Windows drive letter) is treated as a filesystem path.
+func newSource(nameOrURL string, rescanInterval time.Duration) (Source, error) {
+	u, err := url.Parse(nameOrURL)
+	if err != nil || len(u.Scheme) <= 1 {
+		return newFileSource(nameOrURL, rescanInterval), nil
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return newHTTPSource(nameOrURL, rescanInterval), nil
+	default:
+		factory, ok := sourceFactories[u.Scheme]
+		if !ok {
+			return nil, fmt.Errorf("ini: no Source registered for scheme %q; see RegisterSource", u.Scheme)
+		}
+		return factory(nameOrURL, rescanInterval)
+	}
+}
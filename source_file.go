@@ -0,0 +1,107 @@
+package ini
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/golang/glog"
+	"gopkg.in/fsnotify.v1"
+)
+
+// fileSource reads an INI file from the local filesystem and watches it
+// with fsnotify, re-adding the watch on Rename/Remove so an atomic save
+// (temp file + rename) isn't silently dropped, with a periodic mtime
+// rescan as a fallback in case fsnotify misses an event outright.
+type fileSource struct {
+	fileName       string
+	rescanInterval time.Duration
+}
+
+func newFileSource(fileName string, rescanInterval time.Duration) *fileSource {
+	return &fileSource{fileName: fileName, rescanInterval: rescanInterval}
+}
+
+func (s *fileSource) Name() string { return s.fileName }
+
+func (s *fileSource) Read() ([]byte, error) {
+	return ioutil.ReadFile(s.fileName)
+}
+
+func (s *fileSource) Watch() (<-chan struct{}, error) {
+	watchman, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new watcher for INI file err: %v", err)
+	}
+
+	if err = watchman.Add(s.fileName); err != nil {
+		return nil, fmt.Errorf("Failed add new watcher for INI file err: %v", err)
+	}
+
+	changes := make(chan struct{}, 1)
+	go s.watchLoop(watchman, changes)
+	return changes, nil
+}
+
+func (s *fileSource) watchLoop(watchman *fsnotify.Watcher, changes chan<- struct{}) {
+	defer watchman.Close()
+	defer close(changes)
+
+	signal := func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+
+	var rescan <-chan time.Time
+	if s.rescanInterval > 0 {
+		ticker := time.NewTicker(s.rescanInterval)
+		defer ticker.Stop()
+		rescan = ticker.C
+	}
+	lastMod := s.modTime()
+
+	for {
+		select {
+		case event, ok := <-watchman.Events:
+			if !ok {
+				return
+			}
+			glog.V(3).Infoln("Received file watch event: %s", event.String())
+
+			switch {
+			case event.Op&(fsnotify.Rename|fsnotify.Remove) != 0:
+				// Many editors save atomically via a temp file
+				// followed by a rename; fsnotify stops reporting
+				// events for the old inode, so re-add the watch on
+				// the path to pick up the replacement file.
+				if err := watchman.Add(s.fileName); err != nil {
+					glog.Errorf("Failed to re-add watch on %s: %v", s.fileName, err)
+					continue
+				}
+				signal()
+			case event.Op&fsnotify.Write == fsnotify.Write:
+				glog.V(3).Infof("modified file: %s", event.Name)
+				signal()
+			default:
+				glog.V(3).Infof("Ignoring file event: %s", event.String())
+			}
+
+		case <-rescan:
+			if mod := s.modTime(); mod.After(lastMod) {
+				lastMod = mod
+				signal()
+			}
+		}
+	}
+}
+
+func (s *fileSource) modTime() time.Time {
+	info, err := os.Stat(s.fileName)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,149 @@
+package ini
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// httpSource reads an INI file from an HTTP(S) endpoint and polls it on
+// an interval, using If-None-Match/If-Modified-Since so an unchanged
+// endpoint costs a 304 rather than a full reparse.
+type httpSource struct {
+	url      string
+	interval time.Duration
+	client   *http.Client
+
+	lock    sync.Mutex
+	etag    string
+	lastMod string
+	// pending holds the body fetched by the last checkForChange poll
+	// that found a change, so Read doesn't re-fetch it with a second,
+	// unconditional GET.
+	pending []byte
+}
+
+func newHTTPSource(url string, interval time.Duration) *httpSource {
+	if interval <= 0 {
+		interval = defaultRescanInterval
+	}
+	return &httpSource{url: url, interval: interval, client: http.DefaultClient}
+}
+
+func (s *httpSource) Name() string { return s.url }
+
+func (s *httpSource) Read() ([]byte, error) {
+	s.lock.Lock()
+	if s.pending != nil {
+		body := s.pending
+		s.pending = nil
+		s.lock.Unlock()
+		return body, nil
+	}
+	s.lock.Unlock()
+
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d fetching %s", resp.StatusCode, s.url)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	s.etag = resp.Header.Get("ETag")
+	s.lastMod = resp.Header.Get("Last-Modified")
+	s.lock.Unlock()
+
+	return body, nil
+}
+
+func (s *httpSource) Watch() (<-chan struct{}, error) {
+	changes := make(chan struct{}, 1)
+	go s.poll(changes)
+	return changes, nil
+}
+
+func (s *httpSource) poll(changes chan<- struct{}) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		changed, err := s.checkForChange()
+		if err != nil {
+			glog.Errorf("Failed to poll %s: %v", s.url, err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// checkForChange does a conditional GET against the source URL and
+// reports whether the response carried a new body. On a change, the
+// body is cached for the next Read call instead of being discarded, so
+// a detected change costs one GET rather than two.
+func (s *httpSource) checkForChange() (bool, error) {
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return false, err
+	}
+
+	s.lock.Lock()
+	etag, lastMod := s.etag, s.lastMod
+	s.lock.Unlock()
+
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastMod != "" {
+		req.Header.Set("If-Modified-Since", lastMod)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status %d polling %s", resp.StatusCode, s.url)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+
+	s.lock.Lock()
+	s.etag = resp.Header.Get("ETag")
+	s.lastMod = resp.Header.Get("Last-Modified")
+	s.pending = body
+	s.lock.Unlock()
+
+	return true, nil
+}
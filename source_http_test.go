@@ -0,0 +1,66 @@
+package ini
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestHTTPSourceReadAfterChangeReusesBody guards against a regression
+// where a detected change triggered two GETs: one conditional GET in
+// checkForChange to detect the change, and a second, unconditional GET
+// from Read when fd.load() fetched the body. The body found by
+// checkForChange should be reused by the following Read instead of
+// being re-fetched.
+func TestHTTPSourceReadAfterChangeReusesBody(t *testing.T) {
+	var reqCount int32
+	body := []byte("[Server]\nPort = 8080\n")
+	etag := "v1"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&reqCount, 1)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	src := newHTTPSource(server.URL, time.Hour)
+
+	if _, err := src.Read(); err != nil {
+		t.Fatalf("initial Read: %v", err)
+	}
+	if got := atomic.LoadInt32(&reqCount); got != 1 {
+		t.Fatalf("expected 1 request after initial Read, got %d", got)
+	}
+
+	body = []byte("[Server]\nPort = 9090\n")
+	etag = "v2"
+
+	changed, err := src.checkForChange()
+	if err != nil {
+		t.Fatalf("checkForChange: %v", err)
+	}
+	if !changed {
+		t.Fatal("expected checkForChange to report a change")
+	}
+	if got := atomic.LoadInt32(&reqCount); got != 2 {
+		t.Fatalf("expected 2 requests after checkForChange, got %d", got)
+	}
+
+	data, err := src.Read()
+	if err != nil {
+		t.Fatalf("Read after change: %v", err)
+	}
+	if string(data) != string(body) {
+		t.Fatalf("Read returned %q, want %q", data, body)
+	}
+	if got := atomic.LoadInt32(&reqCount); got != 2 {
+		t.Fatalf("Read after a detected change should not re-fetch: expected 2 requests, got %d", got)
+	}
+}
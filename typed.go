@@ -0,0 +1,171 @@
+package ini
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/golang/glog"
+	"gopkg.in/ini.v1"
+)
+
+// KeyChange describes a single section/key whose value changed between
+// two reloads of an IniFile.
+type KeyChange struct {
+	Section string
+	Key     string
+	Old     string
+	New     string
+}
+
+type typedWatcher struct {
+	snapshot interface{} // last value handed to cb, used to detect changes
+	cb       func(old, new interface{}) error
+}
+
+// RegisterTyped maps the file's contents onto v (which must be a
+// pointer, as required by MapContents/ini.MapTo) and keeps a snapshot of
+// the mapped value. On every reload it maps a fresh instance of the same
+// type and, if it differs from the snapshot, invokes cb with the old and
+// new values. Unlike Register, cb is only called when something in v's
+// mapped fields actually changed.
+func (fd *fileDetails) RegisterTyped(v interface{}, cb func(old, new interface{}) error) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		return fmt.Errorf("RegisterTyped: v must be a pointer, got %T", v)
+	}
+
+	if err := fd.mapCurrent(v); err != nil {
+		return err
+	}
+
+	snap := reflect.New(rv.Elem().Type()).Interface()
+	if err := fd.mapCurrent(snap); err != nil {
+		return err
+	}
+
+	fd.typedLock.Lock()
+	fd.typedWatchers = append(fd.typedWatchers, &typedWatcher{snapshot: snap, cb: cb})
+	fd.typedLock.Unlock()
+
+	return nil
+}
+
+// notifyTyped is invoked after every reload. It re-maps each registered
+// type from the current cfg and, if the result differs from the last
+// snapshot handed out, calls the registered callback with (old, new).
+func (fd *fileDetails) notifyTyped() {
+	fd.typedLock.Lock()
+	defer fd.typedLock.Unlock()
+
+	for _, tw := range fd.typedWatchers {
+		newVal := reflect.New(reflect.TypeOf(tw.snapshot).Elem()).Interface()
+		if err := fd.mapCurrent(newVal); err != nil {
+			glog.Errorf("RegisterTyped: failed to map updated config for %s: %v", fd.fileName, err)
+			continue
+		}
+
+		if reflect.DeepEqual(tw.snapshot, newVal) {
+			continue
+		}
+
+		old := tw.snapshot
+		tw.snapshot = newVal
+		go func(cb func(old, new interface{}) error, old, new interface{}) {
+			if err := cb(old, new); err != nil {
+				glog.Errorf("RegisterTyped callback failed for %s: %v", fd.fileName, err)
+			}
+		}(tw.cb, old, newVal)
+	}
+}
+
+// mapCurrent maps the already-loaded cfg onto v, without re-reading the
+// file from disk (unlike MapContents, which always reloads).
+func (fd *fileDetails) mapCurrent(v interface{}) error {
+	fd.lock.RLock()
+	defer fd.lock.RUnlock()
+
+	if fd.cfg == nil {
+		return fmt.Errorf("ini file %s not loaded", fd.fileName)
+	}
+	return fd.cfg.MapTo(v)
+}
+
+// Changed reports whether section/key's value changed on the most
+// recent reload.
+func (fd *fileDetails) Changed(section, key string) bool {
+	fd.diffLock.RLock()
+	defer fd.diffLock.RUnlock()
+
+	for _, c := range fd.lastDiff {
+		if c.Section == section && c.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff returns the section/key changes observed on the most recent
+// reload.
+func (fd *fileDetails) Diff() []KeyChange {
+	fd.diffLock.RLock()
+	defer fd.diffLock.RUnlock()
+
+	diff := make([]KeyChange, len(fd.lastDiff))
+	copy(diff, fd.lastDiff)
+	return diff
+}
+
+// snapshotSections flattens cfg into section -> key -> value, so two
+// snapshots can be diffed with diffSections. A nil cfg yields an empty
+// snapshot.
+func snapshotSections(cfg *ini.File) map[string]map[string]string {
+	snap := make(map[string]map[string]string)
+	if cfg == nil {
+		return snap
+	}
+
+	for _, section := range cfg.Sections() {
+		keys := make(map[string]string)
+		for _, key := range section.Keys() {
+			keys[key.Name()] = key.String()
+		}
+		snap[section.Name()] = keys
+	}
+	return snap
+}
+
+// diffSections compares two flattened snapshots and returns the
+// section/key pairs whose value changed, was added, or was removed.
+func diffSections(before, after map[string]map[string]string) []KeyChange {
+	var changes []KeyChange
+
+	sections := make(map[string]bool)
+	for name := range before {
+		sections[name] = true
+	}
+	for name := range after {
+		sections[name] = true
+	}
+
+	for section := range sections {
+		oldKeys := before[section]
+		newKeys := after[section]
+
+		keys := make(map[string]bool)
+		for k := range oldKeys {
+			keys[k] = true
+		}
+		for k := range newKeys {
+			keys[k] = true
+		}
+
+		for key := range keys {
+			oldVal, hadOld := oldKeys[key]
+			newVal, hasNew := newKeys[key]
+			if oldVal != newVal || hadOld != hasNew {
+				changes = append(changes, KeyChange{Section: section, Key: key, Old: oldVal, New: newVal})
+			}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,53 @@
+package ini
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testServerConfig struct {
+	Server struct {
+		Port string
+	}
+}
+
+// TestRegisterTypedFiresWithoutRegister guards against a regression where
+// notifyTyped was only reached via update() after a plain Register
+// callback existed for the file; a caller using only RegisterTyped never
+// saw its callback invoked.
+func TestRegisterTypedFiresWithoutRegister(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.ini")
+	if err := ioutil.WriteFile(path, []byte("[Server]\nPort = 8080\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := NewIniFile(path, WithDebounce(10*time.Millisecond), WithRescanInterval(0))
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+
+	var cfg testServerConfig
+	fired := make(chan struct{}, 1)
+	if err := f.RegisterTyped(&cfg, func(old, new interface{}) error {
+		select {
+		case fired <- struct{}{}:
+		default:
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("RegisterTyped: %v", err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("[Server]\nPort = 9090\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-fired:
+	case <-time.After(2 * time.Second):
+		t.Fatal("typed callback was not invoked after reload")
+	}
+}
@@ -0,0 +1,45 @@
+package ini
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestDebounceCoalescesBurstOfWrites guards the core of chunk0-1: a
+// burst of writes within the debounce window should trigger a single
+// reload, not one per write.
+func TestDebounceCoalescesBurstOfWrites(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.ini")
+	if err := ioutil.WriteFile(path, []byte("[Server]\nPort = 8080\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := NewIniFile(path, WithDebounce(200*time.Millisecond), WithRescanInterval(0))
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+
+	var reloads int32
+	f.Register(func() {
+		atomic.AddInt32(&reloads, 1)
+	})
+
+	for i := 0; i < 5; i++ {
+		content := []byte("[Server]\nPort = " + string(rune('0'+i)) + "\n")
+		if err := ioutil.WriteFile(path, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Give the debounce window time to collapse the burst and fire a
+	// single reload.
+	time.Sleep(600 * time.Millisecond)
+
+	if got := atomic.LoadInt32(&reloads); got != 1 {
+		t.Fatalf("expected exactly 1 reload after a burst of writes, got %d", got)
+	}
+}
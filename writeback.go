@@ -0,0 +1,136 @@
+package ini
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/ini.v1"
+)
+
+// SetSchema installs the validation schema used on every subsequent
+// load and writeback. A reload that would violate schema is rejected
+// and the previously loaded, known-good config is kept.
+func (fd *fileDetails) SetSchema(schema Schema) {
+	fd.lock.Lock()
+	defer fd.lock.Unlock()
+	fd.schema = &schema
+}
+
+// WriteKey sets section/key to value and persists the change.
+func (fd *fileDetails) WriteKey(section, key, value string) error {
+	return fd.mutateAndSave(func(cfg *ini.File) {
+		cfg.Section(section).Key(key).SetValue(value)
+	})
+}
+
+// SetSection sets every key in kv within section and persists the
+// change.
+func (fd *fileDetails) SetSection(name string, kv map[string]string) error {
+	return fd.mutateAndSave(func(cfg *ini.File) {
+		section := cfg.Section(name)
+		for k, v := range kv {
+			section.Key(k).SetValue(v)
+		}
+	})
+}
+
+// mutateAndSave applies mutate to a scratch copy of fd.cfg, validates
+// the copy against any schema, persists it, and only then swaps it in
+// as the live fd.cfg. Holding fd.lock for the whole mutate+validate+
+// persist sequence keeps a concurrent reload from replacing fd.cfg out
+// from under a pending write, and keeps a rejected write from leaving
+// the live config in the bad state it tried to write.
+func (fd *fileDetails) mutateAndSave(mutate func(cfg *ini.File)) error {
+	fd.lock.Lock()
+	defer fd.lock.Unlock()
+
+	if fd.cfg == nil {
+		return fmt.Errorf("ini file %s not loaded", fd.fileName)
+	}
+	if _, ok := fd.source.(*fileSource); !ok {
+		return fmt.Errorf("writeback not supported for %s: not a file-backed source", fd.fileName)
+	}
+
+	clone, err := cloneConfig(fd.cfg)
+	if err != nil {
+		return fmt.Errorf("failed to prepare writeback copy of %s: %v", fd.fileName, err)
+	}
+	mutate(clone)
+
+	if fd.schema != nil {
+		if err := fd.schema.Validate(clone); err != nil {
+			return fmt.Errorf("refusing to save %s: %v", fd.fileName, err)
+		}
+	}
+
+	if err := fd.persist(clone); err != nil {
+		return err
+	}
+	fd.cfg = clone
+	return nil
+}
+
+// Save validates the in-memory config against any schema set with
+// SetSchema, then writes it to fd.fileName via write-to-temp + rename,
+// so a watcher on the same file observes a single consistent event
+// rather than a partially written file.
+func (fd *fileDetails) Save() error {
+	fd.lock.Lock()
+	defer fd.lock.Unlock()
+
+	if fd.cfg == nil {
+		return fmt.Errorf("ini file %s not loaded", fd.fileName)
+	}
+	if _, ok := fd.source.(*fileSource); !ok {
+		return fmt.Errorf("writeback not supported for %s: not a file-backed source", fd.fileName)
+	}
+
+	if fd.schema != nil {
+		if err := fd.schema.Validate(fd.cfg); err != nil {
+			return fmt.Errorf("refusing to save %s: %v", fd.fileName, err)
+		}
+	}
+
+	return fd.persist(fd.cfg)
+}
+
+// persist writes cfg to fd.fileName via write-to-temp + rename. Callers
+// must hold fd.lock.
+func (fd *fileDetails) persist(cfg *ini.File) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(fd.fileName), ".ini-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file for %s: %v", fd.fileName, err)
+	}
+	tmpName := tmp.Name()
+
+	if _, err := cfg.WriteTo(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to write %s: %v", fd.fileName, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to write %s: %v", fd.fileName, err)
+	}
+
+	if err := os.Rename(tmpName, fd.fileName); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to save %s: %v", fd.fileName, err)
+	}
+
+	return nil
+}
+
+// cloneConfig returns an independent copy of cfg, by round-tripping it
+// through its own serialized form, so a caller can mutate the copy
+// without touching the original until the mutation is known-good.
+func cloneConfig(cfg *ini.File) (*ini.File, error) {
+	var buf bytes.Buffer
+	if _, err := cfg.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return ini.Load(buf.Bytes())
+}
@@ -0,0 +1,109 @@
+package ini
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// TestSaveRejectsNonFileSource guards against a regression where Save
+// unconditionally used fd.fileName as a filesystem path, so an IniFile
+// built over an HTTP source would attempt (and fail) a bogus temp-file
+// write instead of returning a clear "not supported" error.
+func TestSaveRejectsNonFileSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("[Server]\nPort = 8080\n"))
+	}))
+	defer server.Close()
+
+	f, err := NewIniFile(server.URL)
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+
+	if err := f.WriteKey("Server", "Port", "9090"); err == nil {
+		t.Fatal("expected WriteKey over an HTTP source to fail")
+	}
+	if err := f.Save(); err == nil {
+		t.Fatal("expected Save over an HTTP source to fail")
+	}
+}
+
+// TestSaveWritesFileBackedSource exercises the happy path: WriteKey on a
+// file-backed IniFile persists the change via the atomic temp+rename
+// path.
+func TestSaveWritesFileBackedSource(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.ini")
+	if err := ioutil.WriteFile(path, []byte("[Server]\nPort = 8080\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := NewIniFile(path)
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+
+	if err := f.WriteKey("Server", "Port", "9090"); err != nil {
+		t.Fatalf("WriteKey: %v", err)
+	}
+
+	if got := f.ReadKey("Server", "Port", ""); got != "9090" {
+		t.Fatalf("ReadKey after WriteKey = %q, want 9090", got)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != "[Server]\nPort = 9090\n" {
+		t.Fatalf("file contents = %q", got)
+	}
+}
+
+// TestWriteKeyRejectedBySchemaLeavesConfigUnchanged guards against a
+// regression where a schema-rejected WriteKey mutated fd.cfg directly
+// before validating, so a rejected write still corrupted the live,
+// in-memory config even though the file on disk and the returned error
+// correctly reflected the rejection.
+func TestWriteKeyRejectedBySchemaLeavesConfigUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.ini")
+	if err := ioutil.WriteFile(path, []byte("[Server]\nPort = 8080\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := NewIniFile(path)
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+
+	f.SetSchema(Schema{
+		Sections: []SectionSchema{
+			{
+				Name: "Server",
+				Fields: []Field{
+					{Key: "Port", Type: TypeInt, Required: true},
+				},
+			},
+		},
+	})
+
+	if err := f.WriteKey("Server", "Port", "not-a-number"); err == nil {
+		t.Fatal("expected WriteKey to be rejected by the schema")
+	}
+
+	if got := f.ReadKey("Server", "Port", ""); got != "8080" {
+		t.Fatalf("live config should be unchanged after a rejected write, got Port=%q", got)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != "[Server]\nPort = 8080\n" {
+		t.Fatalf("file on disk should be unchanged after a rejected write, got %q", got)
+	}
+}